@@ -0,0 +1,110 @@
+// Package ingester pulls CloudTrail events from their canonical AWS
+// delivery sources (S3, SQS, Kinesis) and hands decoded events off to a
+// handler function, mirroring the notifier package's shape.
+package ingester
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+)
+
+type (
+	// Ingester pulls CloudTrail events from a source and invokes handler
+	// for each one. Run blocks until ctx is canceled or a fatal error
+	// occurs, and should return promptly once it is.
+	Ingester interface {
+		Run(ctx context.Context, handler func(cloudtrail.Event)) error
+		Name() string
+	}
+
+	// logFile is the structure of a CloudTrail digest/log JSON document
+	// as delivered to S3/Kinesis - a single "Records" array of raw
+	// delivery records, kept as json.RawMessage so each one can be
+	// decoded by decodeRawRecord below.
+	logFile struct {
+		Records []json.RawMessage `json:"Records"`
+	}
+
+	// rawRecord is the shape of a single CloudTrail record as AWS
+	// actually delivers it to S3/Kinesis - distinct from cloudtrail.Event,
+	// which is shaped after the LookupEvents API response. Most notably
+	// ReadOnly is a JSON bool here (not a string), the caller identity
+	// lives under userIdentity.userName, and resources use ARN/type
+	// rather than resourceName/resourceType.
+	rawRecord struct {
+		EventID      string `json:"eventID"`
+		EventName    string `json:"eventName"`
+		EventSource  string `json:"eventSource"`
+		EventTime    string `json:"eventTime"`
+		ReadOnly     bool   `json:"readOnly"`
+		UserIdentity struct {
+			UserName string `json:"userName"`
+		} `json:"userIdentity"`
+		Resources []struct {
+			ARN  string `json:"ARN"`
+			Type string `json:"type"`
+		} `json:"resources"`
+	}
+)
+
+// decodeLogFile gunzips and decodes a CloudTrail log file, returning the
+// individual events it contains.
+func decodeLogFile(r io.Reader) ([]cloudtrail.Event, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	var lf logFile
+	if err := json.NewDecoder(gz).Decode(&lf); err != nil {
+		return nil, err
+	}
+	events := make([]cloudtrail.Event, 0, len(lf.Records))
+	for _, raw := range lf.Records {
+		event, err := decodeRawRecord(raw)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// decodeRawRecord maps a single raw delivery record onto a
+// cloudtrail.Event, the representation Stash.discard/processEvent work
+// with, preserving the original record as CloudTrailEvent so RegexField
+// "raw" and rawEventField (errorCode, sourceIPAddress) still work.
+func decodeRawRecord(raw json.RawMessage) (cloudtrail.Event, error) {
+	var rec rawRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return cloudtrail.Event{}, err
+	}
+	event := cloudtrail.Event{
+		EventId:         aws.String(rec.EventID),
+		EventName:       aws.String(rec.EventName),
+		EventSource:     aws.String(rec.EventSource),
+		Username:        aws.String(rec.UserIdentity.UserName),
+		CloudTrailEvent: aws.String(string(raw)),
+	}
+	if rec.ReadOnly {
+		event.ReadOnly = aws.String("true")
+	} else {
+		event.ReadOnly = aws.String("false")
+	}
+	if t, err := time.Parse(time.RFC3339, rec.EventTime); err == nil {
+		event.EventTime = &t
+	}
+	for _, r := range rec.Resources {
+		event.Resources = append(event.Resources, &cloudtrail.Resource{
+			ResourceName: aws.String(r.ARN),
+			ResourceType: aws.String(r.Type),
+		})
+	}
+	return event, nil
+}
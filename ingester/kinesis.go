@@ -0,0 +1,139 @@
+package ingester
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+)
+
+const (
+	// KinesisShardPollInterval is used between GetRecords calls on a shard
+	// that returned no records, to stay under the per-shard rate limit.
+	KinesisShardPollInterval = 5 * time.Second
+)
+
+type (
+	// Kinesis ingests CloudTrail events delivered to a Kinesis stream,
+	// reading every shard from the trim horizon (or latest) and
+	// decoding each record as a single CloudTrail event.
+	Kinesis struct {
+		StreamName string
+		Region     string
+		Latest     bool
+		svc        *kinesis.Kinesis
+	}
+)
+
+// NewKinesis creates a Kinesis ingester from config
+func NewKinesis(config map[string]string) (*Kinesis, error) {
+	k := &Kinesis{}
+	for key, v := range config {
+		switch key {
+		case "streamName":
+			k.StreamName = v
+		case "region":
+			k.Region = v
+		case "latest":
+			k.Latest = v == "true"
+		}
+	}
+	if k.StreamName == "" {
+		return nil, errors.New("missing streamName")
+	}
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(k.Region)})
+	if err != nil {
+		return nil, err
+	}
+	k.svc = kinesis.New(sess)
+	return k, nil
+}
+
+// Name returns the name of the ingester
+func (k *Kinesis) Name() string {
+	return "kinesis"
+}
+
+// Run reads every shard of the stream concurrently until ctx is
+// canceled, an unrecoverable error occurs on any one of them, or every
+// shard has been closed and read to its end.
+func (k *Kinesis) Run(ctx context.Context, handler func(cloudtrail.Event)) error {
+	desc, err := k.svc.DescribeStreamWithContext(ctx, &kinesis.DescribeStreamInput{
+		StreamName: aws.String(k.StreamName),
+	})
+	if err != nil {
+		return err
+	}
+	errCh := make(chan error, len(desc.StreamDescription.Shards))
+	for _, shard := range desc.StreamDescription.Shards {
+		go func(shardID string) {
+			errCh <- k.readShard(ctx, shardID, handler)
+		}(aws.StringValue(shard.ShardId))
+	}
+	for range desc.StreamDescription.Shards {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readShard iterates a single shard, feeding each record to handler,
+// until ctx is canceled, the shard is closed (no next iterator), or an
+// unrecoverable error occurs.
+func (k *Kinesis) readShard(ctx context.Context, shardID string, handler func(cloudtrail.Event)) error {
+	iterType := kinesis.ShardIteratorTypeTrimHorizon
+	if k.Latest {
+		iterType = kinesis.ShardIteratorTypeLatest
+	}
+	iterOut, err := k.svc.GetShardIteratorWithContext(ctx, &kinesis.GetShardIteratorInput{
+		StreamName:        aws.String(k.StreamName),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: aws.String(iterType),
+	})
+	if err != nil {
+		return err
+	}
+	shardIterator := iterOut.ShardIterator
+	for shardIterator != nil {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		out, err := k.svc.GetRecordsWithContext(ctx, &kinesis.GetRecordsInput{
+			ShardIterator: shardIterator,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, rec := range out.Records {
+			event, err := decodeRawRecord(rec.Data)
+			if err != nil {
+				continue
+			}
+			handler(event)
+		}
+		shardIterator = out.NextShardIterator
+		if len(out.Records) == 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(KinesisShardPollInterval):
+			}
+		}
+	}
+	return nil
+}
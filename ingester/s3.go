@@ -0,0 +1,140 @@
+package ingester
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// S3DefaultInterval is used when no poll interval is configured
+	S3DefaultInterval = time.Minute
+)
+
+type (
+	// S3Poll periodically lists a CloudTrail bucket/prefix and ingests
+	// any log files it hasn't seen yet. It exists to bootstrap an
+	// SQS/Kinesis subscription or to run standalone for small setups.
+	S3Poll struct {
+		Bucket   string
+		Prefix   string
+		Region   string
+		Interval time.Duration
+		svc      *s3.S3
+		seen     map[string]bool
+	}
+)
+
+// NewS3Poll creates an S3Poll ingester from config
+func NewS3Poll(config map[string]string) (*S3Poll, error) {
+	s := &S3Poll{seen: make(map[string]bool)}
+	for k, v := range config {
+		switch k {
+		case "bucket":
+			s.Bucket = v
+		case "prefix":
+			s.Prefix = v
+		case "region":
+			s.Region = v
+		case "interval":
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, err
+			}
+			s.Interval = d
+		}
+	}
+	if s.Bucket == "" {
+		return nil, errors.New("missing bucket")
+	}
+	if s.Interval == 0 {
+		s.Interval = S3DefaultInterval
+	}
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(s.Region)})
+	if err != nil {
+		return nil, err
+	}
+	s.svc = s3.New(sess)
+	return s, nil
+}
+
+// Name returns the name of the ingester
+func (s *S3Poll) Name() string {
+	return "s3poll"
+}
+
+// Run polls the bucket every Interval until ctx is canceled or an
+// unrecoverable error occurs
+func (s *S3Poll) Run(ctx context.Context, handler func(cloudtrail.Event)) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	if err := s.poll(ctx, handler); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.poll(ctx, handler); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// poll lists objects under Prefix and processes any log files not
+// already seen, newest last so consumers get events roughly in order.
+func (s *S3Poll) poll(ctx context.Context, handler func(cloudtrail.Event)) error {
+	var keys []string
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(s.Prefix),
+	}
+	err := s.svc.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			key := aws.StringValue(obj.Key)
+			if !s.seen[key] {
+				keys = append(keys, key)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := s.ingestKey(ctx, key, handler); err != nil {
+			return err
+		}
+		s.seen[key] = true
+	}
+	return nil
+}
+
+// ingestKey downloads and decodes a single CloudTrail log file
+func (s *S3Poll) ingestKey(ctx context.Context, key string, handler func(cloudtrail.Event)) error {
+	out, err := s.svc.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	defer out.Body.Close()
+	events, err := decodeLogFile(out.Body)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		handler(event)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package ingester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+const (
+	// SQSWaitTimeSeconds is the long-poll duration used for ReceiveMessage
+	SQSWaitTimeSeconds = 20
+)
+
+type (
+	// SQS ingests CloudTrail logs by consuming S3 ObjectCreated
+	// notifications from an SQS queue, downloading and decoding the
+	// referenced log file for each notification received.
+	SQS struct {
+		QueueURL string
+		Region   string
+		sqsSvc   *sqs.SQS
+		s3Svc    *s3.S3
+	}
+
+	// s3EventNotification is the subset of the S3 bucket notification
+	// payload we care about. It may arrive wrapped in an SNS envelope,
+	// handled in unmarshalS3Event.
+	s3EventNotification struct {
+		Records []struct {
+			S3 struct {
+				Bucket struct {
+					Name string `json:"name"`
+				} `json:"bucket"`
+				Object struct {
+					Key string `json:"key"`
+				} `json:"object"`
+			} `json:"s3"`
+		} `json:"Records"`
+	}
+
+	// snsEnvelope wraps an S3 event notification when the queue is
+	// subscribed to an SNS topic rather than receiving S3 events directly.
+	snsEnvelope struct {
+		Message string `json:"Message"`
+	}
+)
+
+// NewSQS creates an SQS ingester from config
+func NewSQS(config map[string]string) (*SQS, error) {
+	s := &SQS{}
+	for k, v := range config {
+		switch k {
+		case "queueURL":
+			s.QueueURL = v
+		case "region":
+			s.Region = v
+		}
+	}
+	if s.QueueURL == "" {
+		return nil, errors.New("missing queueURL")
+	}
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(s.Region)})
+	if err != nil {
+		return nil, err
+	}
+	s.sqsSvc = sqs.New(sess)
+	s.s3Svc = s3.New(sess)
+	return s, nil
+}
+
+// Name returns the name of the ingester
+func (s *SQS) Name() string {
+	return "sqs"
+}
+
+// Run long-polls the queue until ctx is canceled or an unrecoverable
+// error occurs
+func (s *SQS) Run(ctx context.Context, handler func(cloudtrail.Event)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		out, err := s.sqsSvc.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.QueueURL),
+			MaxNumberOfMessages: aws.Int64(10),
+			WaitTimeSeconds:     aws.Int64(SQSWaitTimeSeconds),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, msg := range out.Messages {
+			if err := s.processMessage(ctx, msg, handler); err != nil {
+				continue
+			}
+			s.sqsSvc.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(s.QueueURL),
+				ReceiptHandle: msg.ReceiptHandle,
+			})
+		}
+	}
+}
+
+// processMessage extracts the bucket/key from a single SQS message,
+// downloads the referenced log file and feeds its events to handler.
+func (s *SQS) processMessage(ctx context.Context, msg *sqs.Message, handler func(cloudtrail.Event)) error {
+	bucket, key, err := unmarshalS3Event(aws.StringValue(msg.Body))
+	if err != nil {
+		return err
+	}
+	out, err := s.s3Svc.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	defer out.Body.Close()
+	events, err := decodeLogFile(out.Body)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		handler(event)
+	}
+	return nil
+}
+
+// unmarshalS3Event parses the bucket/key out of a raw S3 event
+// notification body, unwrapping an SNS envelope if present.
+func unmarshalS3Event(body string) (bucket, key string, err error) {
+	var notification s3EventNotification
+	if err = json.Unmarshal([]byte(body), &notification); err != nil {
+		return "", "", err
+	}
+	if len(notification.Records) == 0 {
+		var envelope snsEnvelope
+		if err = json.Unmarshal([]byte(body), &envelope); err != nil {
+			return "", "", err
+		}
+		if err = json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+			return "", "", err
+		}
+	}
+	if len(notification.Records) == 0 {
+		return "", "", errors.New("no S3 records in notification")
+	}
+	rec := notification.Records[0]
+	return rec.S3.Bucket.Name, rec.S3.Object.Key, nil
+}
@@ -1,105 +1,351 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
-	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/billhathaway/cloudtail/ingester"
 	"github.com/billhathaway/cloudtail/notifier"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// StashGCInterval is how often expired stashes are purged
+	StashGCInterval = time.Minute
 )
 
 type (
-	// Stash is used to filter events
+	// Stash is used to filter events. All non-empty fields must match
+	// for a Stash to discard an event (AND semantics).
 	Stash struct {
 		EventName    string        `json:"event_name,omitempty"`
 		Username     string        `json:"user_name,omitempty"`
 		TTL          time.Duration `json:"ttl,omitempty"`
 		Expiration   time.Time     `json:"expiration,omitempty"`
 		Regex        string        `json:"regex,omitempty"`
+		RegexField   string        `json:"regex_field,omitempty"`
 		ResourceName string        `json:"resource_name,omitempty"`
 		ResourceType string        `json:"resource_type,omitempty"`
+		SourceIPCIDR string        `json:"source_ip_cidr,omitempty"`
 		Description  string        `json:"description,omitempty"`
 		Destinations []string      `json:"destinations,omitempty"`
-		// re           *regexp.Regexp
+		re           *regexp.Regexp
+		ipNet        *net.IPNet
 	}
 	// Controller manages the notifier
 	Controller struct {
-		stashes   map[int]Stash
-		debug     bool
-		notifiers []Notifiers
-		mu        sync.RWMutex
-		log       *log.Logger
+		stashes     map[int]Stash
+		nextStashID int
+		debug       bool
+		notifiers   []*notifierWorker
+		ingesters   []ingester.Ingester
+		store       StashStore
+		retry       RetryPolicy
+		mu          sync.RWMutex
+		log         *logrus.Logger
 	}
 	// Config holds the configuration for the notifier
 	Config struct {
-		Listen    string `json:"listen"`
-		Debug     bool   `json:"debug"`
+		Listen    string      `json:"listen"`
+		Debug     bool        `json:"debug"`
+		StashDB   string      `json:"stash_db,omitempty"`
+		Retry     RetryPolicy `json:"retry,omitempty"`
 		Notifiers map[string]map[string]string
+		Ingesters map[string]map[string]string
 		Stashes   []Stash
 	}
-	// Notifiers are used to send events
+	// Notifiers are used to send events. Send must respect ctx
+	// cancellation/deadline rather than blocking forever.
 	Notifiers interface {
-		Send(cloudtrail.Event) error
+		Send(ctx context.Context, event cloudtrail.Event) error
 		Name() string
 	}
 )
 
-// discard returns true if an event should not be forwarded
-// TODO logic for most fields
+// compile prepares a newly inserted or replaced Stash - the derived
+// fields via compileDerived, plus a fresh Expiration from TTL - and
+// should be called once, from addStash/putStash.
+func (s *Stash) compile() error {
+	if err := s.compileDerived(); err != nil {
+		return err
+	}
+	if s.TTL != 0 {
+		s.Expiration = time.Now().Add(s.TTL)
+	}
+	return nil
+}
+
+// compileDerived rebuilds a Stash's unexported fields - the compiled
+// regex and the parsed CIDR - from its persisted JSON fields, without
+// touching Expiration. It's the half of compile that BoltStashStore.Load
+// needs: a stash read back off disk already has the Expiration it should
+// keep, and restamping it from TTL would reset an about-to-expire stash
+// to a fresh full TTL on every restart.
+func (s *Stash) compileDerived() error {
+	if s.Regex != "" {
+		re, err := regexp.Compile(s.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %s", s.Regex, err)
+		}
+		s.re = re
+	}
+	if s.SourceIPCIDR != "" {
+		_, ipNet, err := net.ParseCIDR(s.SourceIPCIDR)
+		if err != nil {
+			return fmt.Errorf("invalid source_ip_cidr %q: %s", s.SourceIPCIDR, err)
+		}
+		s.ipNet = ipNet
+	}
+	return nil
+}
+
+// expired returns true if the Stash has a non-zero Expiration in the past
+func (s *Stash) expired() bool {
+	return !s.Expiration.IsZero() && time.Now().After(s.Expiration)
+}
+
+// discard returns true if an event should not be forwarded to dest.
+// Every non-empty field on the Stash must match - they combine with AND
+// semantics - and an expired Stash never discards anything.
 func (s *Stash) discard(event cloudtrail.Event, dest string) bool {
-	if s.EventName != "" && s.EventName == aws.StringValue(event.EventName) {
-		return true
+	if s.expired() {
+		return false
+	}
+	if len(s.Destinations) > 0 && !stringInSlice(dest, s.Destinations) {
+		return false
+	}
+	var matched bool
+	if s.EventName != "" {
+		if s.EventName != aws.StringValue(event.EventName) {
+			return false
+		}
+		matched = true
 	}
-	if s.Username != "" && s.Username == aws.StringValue(event.Username) {
+	if s.Username != "" {
+		if s.Username != aws.StringValue(event.Username) {
+			return false
+		}
+		matched = true
+	}
+	if s.re != nil {
+		if !s.re.MatchString(regexTarget(event, s.RegexField)) {
+			return false
+		}
+		matched = true
+	}
+	if s.ResourceName != "" || s.ResourceType != "" {
+		if !matchesResource(event, s.ResourceName, s.ResourceType) {
+			return false
+		}
+		matched = true
+	}
+	if s.ipNet != nil {
+		if !matchesSourceIP(event, s.ipNet) {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// regexTarget returns the string a Stash's Regex should be evaluated
+// against - the event name by default, or the errorCode/raw event JSON
+// when RegexField requests it.
+func regexTarget(event cloudtrail.Event, field string) string {
+	switch field {
+	case "errorcode":
+		return rawEventField(event, "errorCode")
+	case "raw":
+		return aws.StringValue(event.CloudTrailEvent)
+	default:
+		return aws.StringValue(event.EventName)
+	}
+}
+
+// rawEventField pulls a top-level string field out of the raw
+// CloudTrailEvent JSON - used for details AWS doesn't surface on Event
+// itself, such as errorCode or sourceIPAddress.
+func rawEventField(event cloudtrail.Event, key string) string {
+	raw := aws.StringValue(event.CloudTrailEvent)
+	if raw == "" {
+		return ""
+	}
+	var detail map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &detail); err != nil {
+		return ""
+	}
+	v, _ := detail[key].(string)
+	return v
+}
+
+// matchesResource returns true if any of the event's Resources match
+// the given name/type (either may be empty to match any value)
+func matchesResource(event cloudtrail.Event, name, typ string) bool {
+	for _, r := range event.Resources {
+		if name != "" && name != aws.StringValue(r.ResourceName) {
+			continue
+		}
+		if typ != "" && typ != aws.StringValue(r.ResourceType) {
+			continue
+		}
 		return true
 	}
 	return false
 }
 
-func (c *Controller) getStashes(w http.ResponseWriter, r http.Request) {
+// matchesSourceIP returns true if the event's sourceIPAddress falls
+// within ipNet
+func matchesSourceIP(event cloudtrail.Event, ipNet *net.IPNet) bool {
+	ipStr := rawEventField(event, "sourceIPAddress")
+	if ipStr == "" {
+		return false
+	}
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return false
+	}
+	return ipNet.Contains(ip)
+}
+
+func stringInSlice(s string, list []string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
 
+// getStashesHandler returns every stash as JSON
+func (c *Controller) getStashesHandler(w http.ResponseWriter, r *http.Request) {
 	stashes := make(map[int]Stash)
 	c.mu.RLock()
-	for i, stash := range c.stashes {
-		stashes[i] = stash
+	for id, stash := range c.stashes {
+		stashes[id] = stash
+	}
+	c.mu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stashes); err != nil {
+		c.log.WithFields(logrus.Fields{"fn": "getStashesHandler"}).WithError(err).Error("encode failed")
+	}
+}
+
+// getStashHandler returns a single stash as JSON
+func (c *Controller) getStashHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err.Error())
+		return
 	}
+	c.mu.RLock()
+	stash, ok := c.stashes[id]
 	c.mu.RUnlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stash); err != nil {
+		c.log.WithFields(logrus.Fields{"fn": "getStashHandler", "id": id}).WithError(err).Error("encode failed")
+	}
+}
 
+// putStashHandler replaces a single stash
+func (c *Controller) putStashHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err.Error())
+		return
+	}
+	var s Stash
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		c.log.WithFields(logrus.Fields{"fn": "putStashHandler", "event": "decodeStash"}).WithError(err).Error("decode failed")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err.Error())
+		return
+	}
+	if err := c.putStash(id, s); err != nil {
+		c.log.WithFields(logrus.Fields{"fn": "putStashHandler", "id": id}).WithError(err).Error("put failed")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err.Error())
+		return
+	}
+	fmt.Fprintf(w, "stash %d updated\n", id)
+	c.log.WithFields(logrus.Fields{"fn": "putStashHandler", "event": "putStash", "id": id}).Info("stash updated")
 }
-func (c *Controller) processEvent(event cloudtrail.Event) {
+
+// deleteStashHandler removes a single stash
+func (c *Controller) deleteStashHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err.Error())
+		return
+	}
+	c.deleteStash(id)
+	fmt.Fprintf(w, "stash %d deleted\n", id)
+	c.log.WithFields(logrus.Fields{"fn": "deleteStashHandler", "event": "deleteStash", "id": id}).Info("stash deleted")
+}
+
+// processEvent checks event against every stash and hands it off to
+// each non-discarding notifier's worker pool. It only holds mu for the
+// time it takes to snapshot the stashes/notifiers - sends happen
+// entirely off the lock, so a slow notifier can't stall stash
+// mutations. ctx bounds every send this call enqueues, so shutting it
+// down (or its deadline passing) cancels them even after processEvent
+// has returned.
+func (c *Controller) processEvent(ctx context.Context, event cloudtrail.Event) {
+	eventsProcessedTotal.Inc()
+
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	stashes := make(map[int]Stash, len(c.stashes))
+	for id, st := range c.stashes {
+		stashes[id] = st
+	}
+	workers := make([]*notifierWorker, len(c.notifiers))
+	copy(workers, c.notifiers)
+	c.mu.RUnlock()
 
-	for _, sv := range c.notifiers {
-		name := sv.Name()
+	for _, w := range workers {
+		name := w.notifier.Name()
+		var discardedBy int
 		var discard bool
-		for stashID, st := range c.stashes {
+		for stashID, st := range stashes {
 			if st.discard(event, name) {
 				discard = true
-				c.log.Printf("fn=processEvent action=match stash=%d\n", stashID)
+				discardedBy = stashID
 				break
-			} else {
-				c.log.Printf("fn=processEvent action=noMatch stash=%d\n", stashID)
 			}
 		}
 		if discard {
-			c.log.Printf("fn=processEvent action=discard dest=%s id=%s\n", name, aws.StringValue(event.EventId))
+			eventsDiscardedTotal.WithLabelValues(stashLabel(discardedBy)).Inc()
+			c.log.WithFields(logrus.Fields{
+				"fn":    "processEvent",
+				"dest":  name,
+				"id":    aws.StringValue(event.EventId),
+				"stash": discardedBy,
+			}).Debug("discarded")
 			continue
 		}
-		err := sv.Send(event)
-		if err != nil {
-			c.log.Printf("fn=processEvent action=send dest=%s id=%s status=error err=%v\n", name, aws.StringValue(event.EventId), err)
-			continue
-		}
-		c.log.Printf("fn=processEvent action=send dest=%s id=%s status=ok\n", name, aws.StringValue(event.EventId))
+		w.enqueue(ctx, event)
 	}
 }
 
@@ -108,12 +354,12 @@ func (c *Controller) testHandler(w http.ResponseWriter, r *http.Request) {
 	var event cloudtrail.Event
 	err := json.NewDecoder(r.Body).Decode(&event)
 	if err != nil {
-		c.log.Printf("fn=testHandler event=decodeEvent err=%q\n", err)
+		c.log.WithFields(logrus.Fields{"fn": "testHandler", "event": "decodeEvent"}).WithError(err).Error("decode failed")
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintln(w, err.Error())
 		return
 	}
-	c.processEvent(event)
+	c.processEvent(r.Context(), event)
 }
 
 // stashHandler adds a new stash
@@ -121,29 +367,132 @@ func (c *Controller) stashPOSTHandler(w http.ResponseWriter, r *http.Request) {
 	var s Stash
 	err := json.NewDecoder(r.Body).Decode(&s)
 	if err != nil {
-		c.log.Printf("fn=stashPOSTHandler event=decodeStash err=%q\n", err)
+		c.log.WithFields(logrus.Fields{"fn": "stashPOSTHandler", "event": "decodeStash"}).WithError(err).Error("decode failed")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err.Error())
+		return
+	}
+	id, err := c.addStash(s)
+	if err != nil {
+		c.log.WithFields(logrus.Fields{"fn": "stashPOSTHandler", "event": "addStash"}).WithError(err).Error("add failed")
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintln(w, err.Error())
 		return
 	}
-	id := c.addStash(s)
 	fmt.Fprintf(w, "stash %d added\n", id)
-	c.log.Printf("fn=stashPOSTHandler event=addStash id=%d\n", id)
+	c.log.WithFields(logrus.Fields{"fn": "stashPOSTHandler", "event": "addStash", "id": id}).Info("stash added")
 }
 
-func (c *Controller) addStash(s Stash) int {
+// addStash compiles and inserts a Stash, returning its id. Ids come from
+// a monotonic counter rather than len(c.stashes)+1, since deletes and
+// arbitrary-id PUTs make the map sparse and len+1 would collide with
+// (and silently overwrite) an existing stash.
+func (c *Controller) addStash(s Stash) (int, error) {
+	if err := s.compile(); err != nil {
+		return 0, err
+	}
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	stashes := len(c.stashes)
-	nextStash := stashes + 1
-	c.stashes[nextStash] = s
-	return nextStash
+	c.nextStashID++
+	id := c.nextStashID
+	c.stashes[id] = s
+	c.mu.Unlock()
+	if err := c.store.Put(id, s); err != nil {
+		c.log.WithFields(logrus.Fields{"fn": "addStash", "id": id}).WithError(err).Error("persist failed")
+	}
+	return id, nil
+}
+
+// putStash compiles and replaces the stash at id, advancing the counter
+// addStash draws from if id is beyond it so a later POST can't collide
+// with an id a PUT just claimed.
+func (c *Controller) putStash(id int, s Stash) error {
+	if err := s.compile(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.stashes[id] = s
+	if id > c.nextStashID {
+		c.nextStashID = id
+	}
+	c.mu.Unlock()
+	return c.store.Put(id, s)
+}
+
+// deleteStash removes the stash at id
+func (c *Controller) deleteStash(id int) {
+	c.mu.Lock()
+	delete(c.stashes, id)
+	c.mu.Unlock()
+	if err := c.store.Delete(id); err != nil {
+		c.log.WithFields(logrus.Fields{"fn": "deleteStash", "id": id}).WithError(err).Error("persist failed")
+	}
 }
 
 func (c *Controller) addNotifier(s Notifiers) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.notifiers = append(c.notifiers, s)
+	c.notifiers = append(c.notifiers, newNotifierWorker(s, c.retry, c.log))
+}
+
+func (c *Controller) addIngester(i ingester.Ingester) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ingesters = append(c.ingesters, i)
+}
+
+// runIngesters starts every configured ingester in its own goroutine,
+// feeding decoded events into processEvent. Errors are logged; a single
+// ingester failing does not bring down the others. Canceling ctx stops
+// every ingester and is how callers drain them on shutdown.
+func (c *Controller) runIngesters(ctx context.Context) {
+	c.mu.RLock()
+	ingesters := make([]ingester.Ingester, len(c.ingesters))
+	copy(ingesters, c.ingesters)
+	c.mu.RUnlock()
+	for _, ing := range ingesters {
+		go func(ing ingester.Ingester) {
+			c.log.WithFields(logrus.Fields{"fn": "runIngesters", "name": ing.Name()}).Info("starting")
+			handler := func(event cloudtrail.Event) {
+				c.processEvent(ctx, event)
+			}
+			if err := ing.Run(ctx, handler); err != nil {
+				c.log.WithFields(logrus.Fields{"fn": "runIngesters", "name": ing.Name()}).WithError(err).Error("ingester exited")
+			}
+		}(ing)
+	}
+}
+
+// Shutdown stops every notifier worker, canceling any send still in
+// flight, and waits for their pools to drain. Callers should cancel the
+// context passed to runIngesters first so no new events arrive while
+// this blocks.
+func (c *Controller) Shutdown() {
+	c.mu.RLock()
+	workers := make([]*notifierWorker, len(c.notifiers))
+	copy(workers, c.notifiers)
+	c.mu.RUnlock()
+	for _, w := range workers {
+		w.stop()
+	}
+}
+
+// gcStashes periodically removes stashes whose expiration has passed
+func (c *Controller) gcStashes() {
+	ticker := time.NewTicker(StashGCInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		c.mu.Lock()
+		for id, st := range c.stashes {
+			if st.expired() {
+				delete(c.stashes, id)
+				if err := c.store.Delete(id); err != nil {
+					c.log.WithFields(logrus.Fields{"fn": "gcStashes", "id": id}).WithError(err).Error("persist failed")
+				}
+				c.log.WithFields(logrus.Fields{"fn": "gcStashes", "id": id}).Info("expired")
+			}
+		}
+		c.mu.Unlock()
+	}
 }
 
 // Load reads a config and builds a controller
@@ -154,18 +503,62 @@ func Load(r io.Reader) (*Controller, error) {
 		return nil, err
 	}
 	controller := New()
+	if config.Retry != (RetryPolicy{}) {
+		controller.retry = config.Retry.withDefaults()
+	}
 	for sname, sconfig := range config.Notifiers {
-		switch sname {
-		case "stdout":
-			controller.addNotifier(&notifier.Stdout{})
-		case "hipchat":
-			hc, err := notifier.NewHipchat(sconfig)
+		n, err := notifier.New(sname, sconfig)
+		if err != nil {
+			return nil, fmt.Errorf("could not create %s notifier: %s", sname, err)
+		}
+		controller.addNotifier(n)
+	}
+	for iname, iconfig := range config.Ingesters {
+		switch iname {
+		case "s3poll":
+			s3i, err := ingester.NewS3Poll(iconfig)
+			if err != nil {
+				return nil, fmt.Errorf("could not create s3poll ingester: %s", err)
+			}
+			controller.addIngester(s3i)
+		case "sqs":
+			sqsi, err := ingester.NewSQS(iconfig)
+			if err != nil {
+				return nil, fmt.Errorf("could not create sqs ingester: %s", err)
+			}
+			controller.addIngester(sqsi)
+		case "kinesis":
+			ki, err := ingester.NewKinesis(iconfig)
 			if err != nil {
-				return nil, fmt.Errorf("could not create Hipchat notifier: %s", err)
+				return nil, fmt.Errorf("could not create kinesis ingester: %s", err)
 			}
-			controller.addNotifier(hc)
+			controller.addIngester(ki)
 		default:
-			return nil, fmt.Errorf("unknown notifier type %q", sname)
+			return nil, fmt.Errorf("unknown ingester type %q", iname)
+		}
+	}
+	if config.StashDB != "" {
+		store, err := NewBoltStashStore(config.StashDB)
+		if err != nil {
+			return nil, fmt.Errorf("could not open stash db %q: %s", config.StashDB, err)
+		}
+		controller.store = store
+		loaded, err := store.Load()
+		if err != nil {
+			return nil, fmt.Errorf("could not load stashes from %q: %s", config.StashDB, err)
+		}
+		controller.mu.Lock()
+		for id, s := range loaded {
+			controller.stashes[id] = s
+			if id > controller.nextStashID {
+				controller.nextStashID = id
+			}
+		}
+		controller.mu.Unlock()
+	}
+	for _, s := range config.Stashes {
+		if _, err := controller.addStash(s); err != nil {
+			return nil, fmt.Errorf("could not add configured stash: %s", err)
 		}
 	}
 	return controller, nil
@@ -173,11 +566,17 @@ func Load(r io.Reader) (*Controller, error) {
 
 // New creates a new Controller
 func New() *Controller {
+	logger := logrus.New()
+	logger.Out = os.Stderr
 	c := &Controller{
 		stashes:   make(map[int]Stash),
-		notifiers: make([]Notifiers, 0),
-		log:       log.New(os.Stderr, "cloudtail ", log.LstdFlags),
+		notifiers: make([]*notifierWorker, 0),
+		ingesters: make([]ingester.Ingester, 0),
+		store:     nullStashStore{},
+		retry:     RetryPolicy{}.withDefaults(),
+		log:       logger,
 	}
+	go c.gcStashes()
 	return c
 }
 
@@ -200,9 +599,39 @@ func main() {
 	} else {
 		c = New()
 	}
-	http.HandleFunc("/stash", c.stashPOSTHandler)
-	http.HandleFunc("/test", c.testHandler)
-	c.log.Printf("Listening on port %s\n", *port)
-	c.log.Fatalln(http.ListenAndServe(":"+*port, nil))
+	router := mux.NewRouter()
+	router.HandleFunc("/stash", c.getStashesHandler).Methods("GET")
+	router.HandleFunc("/stash", c.stashPOSTHandler).Methods("POST")
+	router.HandleFunc("/stash/{id}", c.getStashHandler).Methods("GET")
+	router.HandleFunc("/stash/{id}", c.putStashHandler).Methods("PUT")
+	router.HandleFunc("/stash/{id}", c.deleteStashHandler).Methods("DELETE")
+	router.HandleFunc("/test", c.testHandler)
+	router.Handle("/metrics", promhttp.Handler())
+
+	ingestCtx, cancelIngest := context.WithCancel(context.Background())
+	c.runIngesters(ingestCtx)
+
+	server := &http.Server{Addr: ":" + *port, Handler: router}
+	go func() {
+		c.log.WithField("port", *port).Info("listening")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			c.log.WithError(err).Fatal("server exited")
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigCh
+	c.log.WithField("signal", sig.String()).Info("shutting down")
+
+	cancelIngest()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		c.log.WithError(err).Error("http server shutdown")
+	}
 
+	c.Shutdown()
+	c.log.Info("shutdown complete")
 }
@@ -0,0 +1,26 @@
+package main
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	eventsProcessedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "cloudtail_events_processed_total",
+		Help: "Total number of CloudTrail events processed",
+	})
+	eventsDiscardedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cloudtail_events_discarded_total",
+		Help: "Total number of events discarded, by stash id",
+	}, []string{"stash"})
+	notifierSendTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cloudtail_notifier_send_total",
+		Help: "Total number of events a notifier delivered (success) or gave up on after exhausting retries (failure), by destination and result",
+	}, []string{"dest", "status"})
+	notifierSendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "cloudtail_notifier_send_duration_seconds",
+		Help: "Notifier send latency in seconds, by destination",
+	}, []string{"dest"})
+)
+
+func init() {
+	prometheus.MustRegister(eventsProcessedTotal, eventsDiscardedTotal, notifierSendTotal, notifierSendDuration)
+}
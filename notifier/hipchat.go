@@ -2,13 +2,13 @@ package notifier
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
-	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudtrail"
 )
 
@@ -24,6 +24,7 @@ type (
 		RoomID   string
 		Token    string
 		From     string
+		client   *http.Client
 	}
 	// HipchatMessage contains a message to send to a Hipchat room
 	HipchatMessage struct {
@@ -32,6 +33,12 @@ type (
 	}
 )
 
+func init() {
+	Register("hipchat", func(config map[string]string) (Notifier, error) {
+		return NewHipchat(config)
+	})
+}
+
 // NewHipchat creates a Hipchar service for notifying
 func NewHipchat(config map[string]string) (*Hipchat, error) {
 	h := &Hipchat{}
@@ -60,18 +67,22 @@ func NewHipchat(config map[string]string) (*Hipchat, error) {
 	if h.Endpoint == "" {
 		h.Endpoint = HipchatDefaultEndoint
 	}
-	fmt.Printf("Hipchat set with %#v\n", h)
+	client, err := newHTTPClient(config["timeout"])
+	if err != nil {
+		return nil, err
+	}
+	h.client = client
 	return h, nil
 }
 
 // Send writes the message
-func (h *Hipchat) Send(e cloudtrail.Event) error {
+func (h *Hipchat) Send(ctx context.Context, e cloudtrail.Event) error {
 
 	// /v2/room/{room_id_or_name}/message
 	url := fmt.Sprintf("%s/v2/room/%s/notification", h.Endpoint, h.RoomID)
 	message := HipchatMessage{
 		From:    h.From,
-		Message: aws.StringValue(e.EventId),
+		Message: Summarize(e),
 	}
 	body, err := json.Marshal(message)
 	if err != nil {
@@ -81,9 +92,10 @@ func (h *Hipchat) Send(e cloudtrail.Event) error {
 	if err != nil {
 		return err
 	}
+	req = req.WithContext(ctx)
 	req.Header.Add("Authorization", "Bearer "+h.Token)
 	req.Header.Add("Content-type", "application/json")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return err
 	}
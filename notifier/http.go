@@ -0,0 +1,20 @@
+package notifier
+
+import (
+	"net/http"
+	"time"
+)
+
+// newHTTPClient builds an http.Client with a configurable send timeout,
+// falling back to DefaultSendTimeout when timeout is empty.
+func newHTTPClient(timeout string) (*http.Client, error) {
+	d := DefaultSendTimeout
+	if timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, err
+		}
+		d = parsed
+	}
+	return &http.Client{Timeout: d}, nil
+}
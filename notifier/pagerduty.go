@@ -0,0 +1,109 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+)
+
+const (
+	// PagerDutyDefaultEndpoint is the PagerDuty Events API v2 endpoint
+	PagerDutyDefaultEndpoint = "https://events.pagerduty.com/v2/enqueue"
+)
+
+type (
+	// PagerDuty triggers incidents via the PagerDuty Events API v2
+	PagerDuty struct {
+		Endpoint   string
+		RoutingKey string
+		client     *http.Client
+	}
+	pagerDutyPayload struct {
+		Summary  string `json:"summary"`
+		Source   string `json:"source"`
+		Severity string `json:"severity"`
+	}
+	pagerDutyEvent struct {
+		RoutingKey  string           `json:"routing_key"`
+		EventAction string           `json:"event_action"`
+		DedupKey    string           `json:"dedup_key"`
+		Payload     pagerDutyPayload `json:"payload"`
+	}
+)
+
+func init() {
+	Register("pagerduty", func(config map[string]string) (Notifier, error) {
+		return NewPagerDuty(config)
+	})
+}
+
+// NewPagerDuty creates a PagerDuty notifier from config
+func NewPagerDuty(config map[string]string) (*PagerDuty, error) {
+	p := &PagerDuty{Endpoint: PagerDutyDefaultEndpoint}
+	for k, v := range config {
+		switch k {
+		case "routingKey":
+			p.RoutingKey = v
+		case "endpoint":
+			p.Endpoint = v
+		}
+	}
+	if p.RoutingKey == "" {
+		return nil, errors.New("missing routingKey")
+	}
+	client, err := newHTTPClient(config["timeout"])
+	if err != nil {
+		return nil, err
+	}
+	p.client = client
+	return p, nil
+}
+
+// Send triggers a PagerDuty event, deduplicated on EventName+Username so
+// repeats of the same action coalesce into one incident instead of paging
+// again.
+func (p *PagerDuty) Send(ctx context.Context, e cloudtrail.Event) error {
+	dedupKey := aws.StringValue(e.EventName) + aws.StringValue(e.Username)
+	event := pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    dedupKey,
+		Payload: pagerDutyPayload{
+			Summary:  Summarize(e),
+			Source:   "cloudtail",
+			Severity: "warning",
+		},
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", p.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Add("Content-type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d - %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// Name returns the name of the service
+func (p *PagerDuty) Name() string {
+	return "PagerDuty"
+}
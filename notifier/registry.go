@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+)
+
+const (
+	// DefaultSendTimeout bounds a single Send call for notifiers that
+	// don't configure their own timeout
+	DefaultSendTimeout = 10 * time.Second
+)
+
+type (
+	// Notifier sends a CloudTrail event to a destination. Send must
+	// respect ctx cancellation/deadline rather than blocking forever.
+	Notifier interface {
+		Send(ctx context.Context, event cloudtrail.Event) error
+		Name() string
+	}
+
+	// Factory builds a Notifier from its config map
+	Factory func(config map[string]string) (Notifier, error)
+)
+
+var factories = make(map[string]Factory)
+
+// Register makes a notifier factory available under name. Backends call
+// this from an init() in their own file so adding a new destination
+// never requires touching the caller.
+func Register(name string, factory Factory) {
+	factories[name] = factory
+}
+
+// New builds the notifier registered under name
+func New(name string, config map[string]string) (Notifier, error) {
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier type %q", name)
+	}
+	return factory(config)
+}
@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+)
+
+type (
+	// Slack service posts messages to a Slack channel via either an
+	// incoming webhook or the chat.postMessage Web API
+	Slack struct {
+		WebhookURL string
+		Token      string
+		Channel    string
+		Username   string
+		client     *http.Client
+	}
+	// slackMessage is the incoming webhook / chat.postMessage payload
+	slackMessage struct {
+		Channel  string `json:"channel,omitempty"`
+		Username string `json:"username,omitempty"`
+		Text     string `json:"text"`
+	}
+)
+
+const (
+	// SlackPostMessageEndpoint is used when Token is set instead of WebhookURL
+	SlackPostMessageEndpoint = "https://slack.com/api/chat.postMessage"
+)
+
+func init() {
+	Register("slack", func(config map[string]string) (Notifier, error) {
+		return NewSlack(config)
+	})
+}
+
+// NewSlack creates a Slack notifier from config
+func NewSlack(config map[string]string) (*Slack, error) {
+	s := &Slack{}
+	for k, v := range config {
+		switch k {
+		case "webhookURL":
+			s.WebhookURL = v
+		case "token":
+			s.Token = v
+		case "channel":
+			s.Channel = v
+		case "username":
+			s.Username = v
+		}
+	}
+	if s.WebhookURL == "" && s.Token == "" {
+		return nil, errors.New("missing webhookURL or token")
+	}
+	if s.Token != "" && s.Channel == "" {
+		return nil, errors.New("missing channel")
+	}
+	client, err := newHTTPClient(config["timeout"])
+	if err != nil {
+		return nil, err
+	}
+	s.client = client
+	return s, nil
+}
+
+// Send posts the event summary to Slack, using the chat.postMessage API
+// when Token is set and falling back to the incoming webhook
+func (s *Slack) Send(ctx context.Context, e cloudtrail.Event) error {
+	message := slackMessage{
+		Channel:  s.Channel,
+		Username: s.Username,
+		Text:     Summarize(e),
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	url := s.WebhookURL
+	if s.Token != "" {
+		url = SlackPostMessageEndpoint
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Add("Content-type", "application/json")
+	if s.Token != "" {
+		req.Header.Add("Authorization", "Bearer "+s.Token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d - %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// Name returns the name of the service
+func (s *Slack) Name() string {
+	return "Slack"
+}
@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/sns"
+)
+
+type (
+	// SNS publishes event summaries to an AWS SNS topic
+	SNS struct {
+		TopicARN string
+		Region   string
+		svc      *sns.SNS
+	}
+)
+
+func init() {
+	Register("sns", func(config map[string]string) (Notifier, error) {
+		return NewSNS(config)
+	})
+}
+
+// NewSNS creates an SNS notifier from config
+func NewSNS(config map[string]string) (*SNS, error) {
+	s := &SNS{}
+	for k, v := range config {
+		switch k {
+		case "topicARN":
+			s.TopicARN = v
+		case "region":
+			s.Region = v
+		}
+	}
+	if s.TopicARN == "" {
+		return nil, errors.New("missing topicARN")
+	}
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(s.Region)})
+	if err != nil {
+		return nil, err
+	}
+	s.svc = sns.New(sess)
+	return s, nil
+}
+
+// Send publishes the event summary to the configured SNS topic
+func (s *SNS) Send(ctx context.Context, e cloudtrail.Event) error {
+	_, err := s.svc.PublishWithContext(ctx, &sns.PublishInput{
+		TopicArn: aws.String(s.TopicARN),
+		Subject:  aws.String("cloudtail: " + aws.StringValue(e.EventName)),
+		Message:  aws.String(Summarize(e)),
+	})
+	return err
+}
+
+// Name returns the name of the service
+func (s *SNS) Name() string {
+	return "SNS"
+}
@@ -1,6 +1,7 @@
 package notifier
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 
@@ -14,8 +15,17 @@ type (
 	}
 )
 
+func init() {
+	Register("stdout", func(config map[string]string) (Notifier, error) {
+		return &Stdout{}, nil
+	})
+}
+
 // Send writes the message
-func (s *Stdout) Send(e cloudtrail.Event) error {
+func (s *Stdout) Send(ctx context.Context, e cloudtrail.Event) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if s.enc == nil {
 		s.enc = json.NewEncoder(os.Stdout)
 	}
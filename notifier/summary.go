@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+)
+
+// Summarize formats a CloudTrail event into a short, human-readable
+// message suitable for chat/paging destinations, rather than just an
+// EventId.
+func Summarize(e cloudtrail.Event) string {
+	msg := fmt.Sprintf("%s called %s", aws.StringValue(e.Username), aws.StringValue(e.EventName))
+	var resources []string
+	for _, r := range e.Resources {
+		if name := aws.StringValue(r.ResourceName); name != "" {
+			resources = append(resources, name)
+		}
+	}
+	if len(resources) > 0 {
+		msg += fmt.Sprintf(" on %s", strings.Join(resources, ", "))
+	}
+	if e.EventTime != nil {
+		msg += fmt.Sprintf(" at %s", e.EventTime.Format(time.RFC3339))
+	}
+	return msg
+}
@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+)
+
+type (
+	// Webhook POSTs the raw event JSON to a configurable HTTP endpoint,
+	// optionally signing the body with HMAC-SHA256
+	Webhook struct {
+		URL     string
+		Secret  string
+		Headers map[string]string
+		client  *http.Client
+	}
+)
+
+func init() {
+	Register("webhook", func(config map[string]string) (Notifier, error) {
+		return NewWebhook(config)
+	})
+}
+
+// NewWebhook creates a Webhook notifier from config. A config key
+// prefixed "header." is sent as a request header on every send, e.g.
+// "header.X-Api-Key" -> "X-Api-Key" header.
+func NewWebhook(config map[string]string) (*Webhook, error) {
+	w := &Webhook{Headers: make(map[string]string)}
+	for k, v := range config {
+		switch {
+		case k == "url":
+			w.URL = v
+		case k == "secret":
+			w.Secret = v
+		case strings.HasPrefix(k, "header."):
+			w.Headers[strings.TrimPrefix(k, "header.")] = v
+		}
+	}
+	if w.URL == "" {
+		return nil, errors.New("missing url")
+	}
+	client, err := newHTTPClient(config["timeout"])
+	if err != nil {
+		return nil, err
+	}
+	w.client = client
+	return w, nil
+}
+
+// Send POSTs the raw event JSON to the configured URL
+func (w *Webhook) Send(ctx context.Context, e cloudtrail.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Add("Content-type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Add(k, v)
+	}
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Add("X-Cloudtail-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d - %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// Name returns the name of the service
+func (w *Webhook) Name() string {
+	return "Webhook"
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// NotifierQueueSize bounds how many pending sends are buffered per
+	// notifier before processEvent starts dropping events for it.
+	NotifierQueueSize = 100
+	// NotifierPoolSize is how many sends a single notifier processes
+	// concurrently, so one slow destination can't starve the others.
+	NotifierPoolSize = 4
+)
+
+type (
+	// notifierJob pairs an event with the context under which it was
+	// enqueued, so a send started well after enqueue still honors the
+	// caller's deadline/cancellation.
+	notifierJob struct {
+		ctx   context.Context
+		event cloudtrail.Event
+	}
+
+	// notifierWorker runs a bounded pool of goroutines that pull jobs
+	// off queue and send them through notifier, retrying on failure.
+	// It exists so processEvent can hand off a send without blocking on
+	// a slow destination or holding Controller.mu.
+	//
+	// stopCh is closed exactly once by stop, and every outstanding send
+	// is watching it via withStop - the same shared cancel-channel
+	// pattern gonet's Conn uses for SetDeadline, where a single close
+	// cancels every blocked read/write at once instead of each needing
+	// its own timer.
+	notifierWorker struct {
+		notifier Notifiers
+		queue    chan notifierJob
+		retry    RetryPolicy
+		log      *logrus.Logger
+		stopCh   chan struct{}
+		stopOnce sync.Once
+		wg       sync.WaitGroup
+	}
+)
+
+// newNotifierWorker creates a notifierWorker and starts its pool
+func newNotifierWorker(n Notifiers, retry RetryPolicy, log *logrus.Logger) *notifierWorker {
+	w := &notifierWorker{
+		notifier: n,
+		queue:    make(chan notifierJob, NotifierQueueSize),
+		retry:    retry.withDefaults(),
+		log:      log,
+		stopCh:   make(chan struct{}),
+	}
+	w.wg.Add(NotifierPoolSize)
+	for i := 0; i < NotifierPoolSize; i++ {
+		go w.run()
+	}
+	return w
+}
+
+// enqueue hands event to the worker's pool, dropping it if the queue is
+// full rather than blocking the caller.
+func (w *notifierWorker) enqueue(ctx context.Context, event cloudtrail.Event) {
+	select {
+	case w.queue <- notifierJob{ctx: ctx, event: event}:
+	default:
+		w.log.WithFields(logrus.Fields{
+			"fn":   "notifierWorker.enqueue",
+			"dest": w.notifier.Name(),
+			"id":   aws.StringValue(event.EventId),
+		}).Warn("notifier queue full, dropping event")
+	}
+}
+
+// stop closes stopCh, canceling every send currently in flight, and waits
+// for the pool to drain so Controller.Shutdown can return once every
+// notifier goroutine has actually exited.
+func (w *notifierWorker) stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+	w.wg.Wait()
+}
+
+// run pulls jobs off queue until stopCh is closed. It selects on both
+// rather than ranging over queue, since nothing ever closes queue -
+// enqueue keeps writing to it from other goroutines right up to
+// shutdown, so waiting only on a queue close would hang forever. Once
+// stopCh fires, any jobs already buffered in queue are still passed to
+// send rather than left in the channel, but because stopCh is closed,
+// withStop cancels each of their contexts at once, so those sends are
+// normally aborted and logged rather than delivered.
+func (w *notifierWorker) run() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.stopCh:
+			for {
+				select {
+				case job := <-w.queue:
+					w.send(job)
+				default:
+					return
+				}
+			}
+		case job := <-w.queue:
+			w.send(job)
+		}
+	}
+}
+
+// withStop derives a context that is canceled when either ctx is done or
+// stopCh is closed, so a shutdown in progress aborts an in-flight send
+// the same way the job's own deadline would.
+func withStop(ctx context.Context, stopCh <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// send delivers job, retrying with exponential backoff and jitter on
+// failure, and records metrics for every attempt.
+func (w *notifierWorker) send(job notifierJob) {
+	name := w.notifier.Name()
+	event := job.event
+	fields := logrus.Fields{"fn": "notifierWorker.send", "dest": name, "id": aws.StringValue(event.EventId)}
+	var err error
+	for attempt := 0; attempt < w.retry.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-w.stopCh:
+				w.log.WithFields(fields).Warn("send aborted, notifier stopping")
+				return
+			case <-time.After(w.retry.backoff(attempt - 1)):
+			}
+		}
+		ctx, cancel := withStop(job.ctx, w.stopCh)
+		start := time.Now()
+		err = w.notifier.Send(ctx, event)
+		cancel()
+		notifierSendDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err == nil {
+			notifierSendTotal.WithLabelValues(name, "success").Inc()
+			w.log.WithFields(fields).WithField("attempt", attempt+1).Info("send ok")
+			return
+		}
+		w.log.WithFields(fields).WithField("attempt", attempt+1).WithError(err).Warn("send failed")
+	}
+	notifierSendTotal.WithLabelValues(name, "failure").Inc()
+	w.log.WithFields(fields).WithError(err).Error("send exhausted retries")
+}
+
+// stashLabel turns a stash id into the label value used on
+// eventsDiscardedTotal
+func stashLabel(id int) string {
+	return strconv.Itoa(id)
+}
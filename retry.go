@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is used when a RetryPolicy doesn't set one
+	DefaultMaxAttempts = 3
+	// DefaultBaseDelay is used when a RetryPolicy doesn't set one
+	DefaultBaseDelay = 500 * time.Millisecond
+	// DefaultMaxDelay is used when a RetryPolicy doesn't set one
+	DefaultMaxDelay = 30 * time.Second
+)
+
+type (
+	// RetryPolicy controls how a failed notifier send is retried -
+	// exponential backoff with full jitter between attempts.
+	RetryPolicy struct {
+		MaxAttempts int           `json:"max_attempts,omitempty"`
+		BaseDelay   time.Duration `json:"base_delay,omitempty"`
+		MaxDelay    time.Duration `json:"max_delay,omitempty"`
+	}
+)
+
+// withDefaults fills in any unset fields of p
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultMaxAttempts
+	}
+	if p.BaseDelay == 0 {
+		p.BaseDelay = DefaultBaseDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = DefaultMaxDelay
+	}
+	return p
+}
+
+// backoff returns a jittered exponential backoff duration for the given
+// zero-indexed retry attempt, capped at MaxDelay.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay * time.Duration(int64(1)<<uint(attempt))
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
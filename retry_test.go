@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	cases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 0, max: 100 * time.Millisecond},
+		{attempt: 1, max: 200 * time.Millisecond},
+		{attempt: 2, max: 400 * time.Millisecond},
+		{attempt: 10, max: time.Second}, // exceeds MaxDelay, clamped
+	}
+	for _, c := range cases {
+		for i := 0; i < 20; i++ {
+			d := p.backoff(c.attempt)
+			if d < 0 || d > c.max {
+				t.Fatalf("attempt %d: backoff %s out of range [0, %s]", c.attempt, d, c.max)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyBackoffZeroMaxDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 0}
+	if d := p.backoff(0); d != 0 {
+		t.Fatalf("expected 0 backoff when MaxDelay is 0, got %s", d)
+	}
+}
+
+func TestRetryPolicyWithDefaults(t *testing.T) {
+	p := RetryPolicy{}.withDefaults()
+	if p.MaxAttempts != DefaultMaxAttempts {
+		t.Errorf("MaxAttempts = %d, want %d", p.MaxAttempts, DefaultMaxAttempts)
+	}
+	if p.BaseDelay != DefaultBaseDelay {
+		t.Errorf("BaseDelay = %s, want %s", p.BaseDelay, DefaultBaseDelay)
+	}
+	if p.MaxDelay != DefaultMaxDelay {
+		t.Errorf("MaxDelay = %s, want %s", p.MaxDelay, DefaultMaxDelay)
+	}
+
+	custom := RetryPolicy{MaxAttempts: 5}.withDefaults()
+	if custom.MaxAttempts != 5 {
+		t.Errorf("MaxAttempts = %d, want 5", custom.MaxAttempts)
+	}
+	if custom.BaseDelay != DefaultBaseDelay {
+		t.Errorf("BaseDelay = %s, want %s", custom.BaseDelay, DefaultBaseDelay)
+	}
+}
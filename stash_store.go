@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/boltdb/bolt"
+)
+
+var stashBucket = []byte("stashes")
+
+type (
+	// StashStore persists the stash map so it survives restarts
+	StashStore interface {
+		// Load returns every persisted stash, keyed by id
+		Load() (map[int]Stash, error)
+		// Put persists a single stash
+		Put(id int, s Stash) error
+		// Delete removes a persisted stash
+		Delete(id int) error
+		// Close releases any resources held by the store
+		Close() error
+	}
+
+	// BoltStashStore is a StashStore backed by a local BoltDB file
+	BoltStashStore struct {
+		db *bolt.DB
+	}
+
+	// nullStashStore is the default StashStore for a Controller with no
+	// persistence configured
+	nullStashStore struct{}
+)
+
+// NewBoltStashStore opens (creating if needed) a BoltDB file at path
+func NewBoltStashStore(path string) (*BoltStashStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(stashBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStashStore{db: db}, nil
+}
+
+// Load reads every persisted stash
+func (b *BoltStashStore) Load() (map[int]Stash, error) {
+	stashes := make(map[int]Stash)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(stashBucket).ForEach(func(k, v []byte) error {
+			id, err := strconv.Atoi(string(k))
+			if err != nil {
+				return err
+			}
+			var s Stash
+			if err := json.Unmarshal(v, &s); err != nil {
+				return err
+			}
+			if err := s.compileDerived(); err != nil {
+				return err
+			}
+			stashes[id] = s
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stashes, nil
+}
+
+// Put persists a single stash
+func (b *BoltStashStore) Put(id int, s Stash) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stashBucket).Put([]byte(strconv.Itoa(id)), data)
+	})
+}
+
+// Delete removes a persisted stash
+func (b *BoltStashStore) Delete(id int) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stashBucket).Delete([]byte(strconv.Itoa(id)))
+	})
+}
+
+// Close closes the underlying BoltDB file
+func (b *BoltStashStore) Close() error {
+	return b.db.Close()
+}
+
+func (nullStashStore) Load() (map[int]Stash, error) { return nil, nil }
+func (nullStashStore) Put(id int, s Stash) error    { return nil }
+func (nullStashStore) Delete(id int) error          { return nil }
+func (nullStashStore) Close() error                 { return nil }
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+)
+
+func TestStashDiscardEventName(t *testing.T) {
+	s := Stash{EventName: "DeleteBucket"}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	matching := cloudtrail.Event{EventName: aws.String("DeleteBucket")}
+	other := cloudtrail.Event{EventName: aws.String("CreateBucket")}
+	if !s.discard(matching, "stdout") {
+		t.Error("expected matching EventName to be discarded")
+	}
+	if s.discard(other, "stdout") {
+		t.Error("expected non-matching EventName not to be discarded")
+	}
+}
+
+func TestStashDiscardUsername(t *testing.T) {
+	s := Stash{Username: "alice"}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	event := cloudtrail.Event{Username: aws.String("alice")}
+	if !s.discard(event, "stdout") {
+		t.Error("expected matching Username to be discarded")
+	}
+	event.Username = aws.String("bob")
+	if s.discard(event, "stdout") {
+		t.Error("expected non-matching Username not to be discarded")
+	}
+}
+
+func TestStashDiscardRegex(t *testing.T) {
+	s := Stash{Regex: "^Delete.*"}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.discard(cloudtrail.Event{EventName: aws.String("DeleteBucket")}, "stdout") {
+		t.Error("expected matching Regex to be discarded")
+	}
+	if s.discard(cloudtrail.Event{EventName: aws.String("CreateBucket")}, "stdout") {
+		t.Error("expected non-matching Regex not to be discarded")
+	}
+}
+
+func TestStashDiscardRegexField(t *testing.T) {
+	s := Stash{Regex: "AccessDenied", RegexField: "errorcode"}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	event := cloudtrail.Event{
+		CloudTrailEvent: aws.String(`{"errorCode":"AccessDenied"}`),
+	}
+	if !s.discard(event, "stdout") {
+		t.Error("expected errorcode regex match to be discarded")
+	}
+	event.CloudTrailEvent = aws.String(`{"errorCode":"Success"}`)
+	if s.discard(event, "stdout") {
+		t.Error("expected errorcode regex mismatch not to be discarded")
+	}
+}
+
+func TestStashDiscardResource(t *testing.T) {
+	s := Stash{ResourceName: "my-bucket", ResourceType: "AWS::S3::Bucket"}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	matching := cloudtrail.Event{Resources: []*cloudtrail.Resource{
+		{ResourceName: aws.String("my-bucket"), ResourceType: aws.String("AWS::S3::Bucket")},
+	}}
+	if !s.discard(matching, "stdout") {
+		t.Error("expected matching resource to be discarded")
+	}
+	other := cloudtrail.Event{Resources: []*cloudtrail.Resource{
+		{ResourceName: aws.String("other-bucket"), ResourceType: aws.String("AWS::S3::Bucket")},
+	}}
+	if s.discard(other, "stdout") {
+		t.Error("expected non-matching resource not to be discarded")
+	}
+}
+
+func TestStashDiscardSourceIPCIDR(t *testing.T) {
+	s := Stash{SourceIPCIDR: "10.0.0.0/8"}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	inRange := cloudtrail.Event{CloudTrailEvent: aws.String(`{"sourceIPAddress":"10.1.2.3"}`)}
+	if !s.discard(inRange, "stdout") {
+		t.Error("expected in-range source IP to be discarded")
+	}
+	outOfRange := cloudtrail.Event{CloudTrailEvent: aws.String(`{"sourceIPAddress":"192.168.1.1"}`)}
+	if s.discard(outOfRange, "stdout") {
+		t.Error("expected out-of-range source IP not to be discarded")
+	}
+}
+
+func TestStashDiscardDestinations(t *testing.T) {
+	s := Stash{EventName: "DeleteBucket", Destinations: []string{"slack"}}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	event := cloudtrail.Event{EventName: aws.String("DeleteBucket")}
+	if !s.discard(event, "slack") {
+		t.Error("expected discard for a listed destination")
+	}
+	if s.discard(event, "stdout") {
+		t.Error("expected no discard for a destination not in Destinations")
+	}
+}
+
+func TestStashDiscardAndSemantics(t *testing.T) {
+	s := Stash{EventName: "DeleteBucket", Username: "alice"}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	// Only EventName matches - AND semantics means this must not discard.
+	event := cloudtrail.Event{EventName: aws.String("DeleteBucket"), Username: aws.String("bob")}
+	if s.discard(event, "stdout") {
+		t.Error("expected partial match not to be discarded under AND semantics")
+	}
+	event.Username = aws.String("alice")
+	if !s.discard(event, "stdout") {
+		t.Error("expected full match to be discarded")
+	}
+}
+
+func TestStashDiscardEmptyStashNeverMatches(t *testing.T) {
+	var s Stash
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	if s.discard(cloudtrail.Event{EventName: aws.String("Anything")}, "stdout") {
+		t.Error("expected an all-empty Stash never to discard")
+	}
+}
+
+func TestStashExpired(t *testing.T) {
+	s := Stash{EventName: "DeleteBucket", TTL: -time.Minute}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.expired() {
+		t.Error("expected a Stash with a past TTL to be expired")
+	}
+	event := cloudtrail.Event{EventName: aws.String("DeleteBucket")}
+	if s.discard(event, "stdout") {
+		t.Error("expected an expired Stash never to discard")
+	}
+}
+
+func TestStashCompileDerivedPreservesExpiration(t *testing.T) {
+	s := Stash{TTL: time.Hour}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	original := s.Expiration
+
+	// Simulate reloading this Stash from the store: compileDerived must
+	// not restamp Expiration the way compile does, or a TTL-based stash
+	// would get its suppression window reset on every restart.
+	reloaded := s
+	if err := reloaded.compileDerived(); err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.Expiration.Equal(original) {
+		t.Errorf("compileDerived changed Expiration from %s to %s", original, reloaded.Expiration)
+	}
+}